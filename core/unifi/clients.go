@@ -0,0 +1,21 @@
+package unifi
+
+// Client is a device (phone, laptop, IoT thing) connected to a Unifi network.
+type Client struct {
+	ID        string   `json:"_id"`
+	SiteName  string   `json:"-"`
+	Mac       string   `json:"mac"`
+	Name      string   `json:"name"`
+	Hostname  string   `json:"hostname"`
+	IP        string   `json:"ip"`
+	Network   string   `json:"network"`
+	ApMac     string   `json:"ap_mac"`
+	SwMac     string   `json:"sw_mac"`
+	IsWired   FlexBool `json:"is_wired"`
+	IsGuest   FlexBool `json:"is_guest"`
+	FirstSeen FlexInt  `json:"first_seen"`
+	LastSeen  FlexInt  `json:"last_seen"`
+	Uptime    FlexInt  `json:"uptime"`
+	TxBytes   FlexInt  `json:"tx_bytes"`
+	RxBytes   FlexInt  `json:"rx_bytes"`
+}
@@ -0,0 +1,119 @@
+package unifi
+
+import "encoding/json"
+
+// UAP is a Unifi Access Point.
+type UAP struct {
+	ID       string   `json:"_id"`
+	SiteName string   `json:"-"`
+	Mac      string   `json:"mac"`
+	Name     string   `json:"name"`
+	IP       string   `json:"ip"`
+	Model    string   `json:"model"`
+	Serial   string   `json:"serial"`
+	Version  string   `json:"version"`
+	Adopted  FlexBool `json:"adopted"`
+	State    FlexInt  `json:"state"`
+	Uptime   FlexInt  `json:"uptime"`
+}
+
+// USW is a Unifi Switch.
+type USW struct {
+	ID       string   `json:"_id"`
+	SiteName string   `json:"-"`
+	Mac      string   `json:"mac"`
+	Name     string   `json:"name"`
+	IP       string   `json:"ip"`
+	Model    string   `json:"model"`
+	Serial   string   `json:"serial"`
+	Version  string   `json:"version"`
+	Adopted  FlexBool `json:"adopted"`
+	State    FlexInt  `json:"state"`
+	Uptime   FlexInt  `json:"uptime"`
+}
+
+// USG is a Unifi Security Gateway.
+type USG struct {
+	ID       string   `json:"_id"`
+	SiteName string   `json:"-"`
+	Mac      string   `json:"mac"`
+	Name     string   `json:"name"`
+	IP       string   `json:"ip"`
+	Model    string   `json:"model"`
+	Serial   string   `json:"serial"`
+	Version  string   `json:"version"`
+	Adopted  FlexBool `json:"adopted"`
+	State    FlexInt  `json:"state"`
+	Uptime   FlexInt  `json:"uptime"`
+}
+
+// UDM is a Unifi Dream Machine (or Dream Machine Pro, UDR, Cloud Gateway, etc).
+type UDM struct {
+	ID       string   `json:"_id"`
+	SiteName string   `json:"-"`
+	Mac      string   `json:"mac"`
+	Name     string   `json:"name"`
+	IP       string   `json:"ip"`
+	Model    string   `json:"model"`
+	Serial   string   `json:"serial"`
+	Version  string   `json:"version"`
+	Adopted  FlexBool `json:"adopted"`
+	State    FlexInt  `json:"state"`
+	Uptime   FlexInt  `json:"uptime"`
+}
+
+// deviceType is used to peek at a device's type before deciding which
+// concrete struct to unmarshal it into.
+type deviceType struct {
+	Type string `json:"type"`
+}
+
+// addRaw unmarshals a list of raw device-stat JSON blobs returned by
+// APIDevicePath and appends each to the matching slice on Devices based
+// on its "type" field. siteName is stamped onto every device because the
+// Unifi API does not include it in the device payload itself.
+func (d *Devices) addRaw(raw []json.RawMessage, siteName string) error {
+	for _, r := range raw {
+		var t deviceType
+		if err := json.Unmarshal(r, &t); err != nil {
+			return err
+		}
+
+		switch t.Type {
+		case "uap":
+			dev := &UAP{}
+			if err := json.Unmarshal(r, dev); err != nil {
+				return err
+			}
+
+			dev.SiteName = siteName
+			d.UAPs = append(d.UAPs, dev)
+		case "usw":
+			dev := &USW{}
+			if err := json.Unmarshal(r, dev); err != nil {
+				return err
+			}
+
+			dev.SiteName = siteName
+			d.USWs = append(d.USWs, dev)
+		case "usg", "ugw":
+			dev := &USG{}
+			if err := json.Unmarshal(r, dev); err != nil {
+				return err
+			}
+
+			dev.SiteName = siteName
+			d.USGs = append(d.USGs, dev)
+		case "udm":
+			dev := &UDM{}
+			if err := json.Unmarshal(r, dev); err != nil {
+				return err
+			}
+
+			dev.SiteName = siteName
+			d.UDMs = append(d.UDMs, dev)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package unifi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDoRequestReauthOn401(t *testing.T) {
+	t.Parallel()
+
+	var deviceHits, loginHits int32
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case APILoginPath:
+			atomic.AddInt32(&loginHits, 1)
+			w.Header().Set("X-CSRF-Token", "new-csrf-token")
+			w.WriteHeader(http.StatusOK)
+		case "/test/path":
+			if atomic.AddInt32(&deviceHits, 1) == 1 {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"data":[{"ok":true}]}`)) //nolint:errcheck
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	u := &Unifi{
+		Config: &Config{URL: ts.URL, User: "admin", Pass: "admin", ErrorLog: discardLogs, DebugLog: discardLogs},
+		Client: ts.Client(),
+	}
+
+	var out []map[string]interface{}
+
+	if err := u.doRequest(context.Background(), http.MethodGet, "/test/path", "", &out); err != nil {
+		t.Fatalf("doRequest() error = %v", err)
+	}
+
+	if got := atomic.LoadInt32(&deviceHits); got != 2 {
+		t.Errorf("test endpoint hit %d times, want 2 (initial 401, then the post-reauth replay)", got)
+	}
+
+	if got := atomic.LoadInt32(&loginHits); got != 1 {
+		t.Errorf("login endpoint hit %d times, want 1", got)
+	}
+
+	if len(out) != 1 || out[0]["ok"] != true {
+		t.Errorf("decoded data = %#v, want [{ok:true}]", out)
+	}
+}
+
+func TestDoRequestRetryExhausted(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	u := &Unifi{
+		Config: &Config{
+			URL:            ts.URL,
+			ErrorLog:       discardLogs,
+			DebugLog:       discardLogs,
+			MaxRetries:     1,
+			RetryBaseDelay: time.Millisecond,
+		},
+		Client: ts.Client(),
+	}
+
+	err := u.doRequest(context.Background(), http.MethodGet, "/test/path", "", nil)
+	if !errors.Is(err, ErrTransport) {
+		t.Fatalf("doRequest() error = %v, want wrapping ErrTransport", err)
+	}
+}
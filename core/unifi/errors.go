@@ -0,0 +1,16 @@
+package unifi
+
+import "github.com/pkg/errors"
+
+var (
+	// ErrUnauthorized is returned when a request is rejected as
+	// unauthenticated (401/403) even after an automatic re-login attempt,
+	// or at the first rejection when an APIKey is in use.
+	ErrUnauthorized = errors.New("unifi: unauthorized")
+	// ErrRateLimited is returned when a request keeps getting a 429 after
+	// exhausting Config.MaxRetries retries.
+	ErrRateLimited = errors.New("unifi: rate limited")
+	// ErrTransport is returned when a request fails to reach the controller,
+	// or keeps getting a 5xx after exhausting Config.MaxRetries retries.
+	ErrTransport = errors.New("unifi: transport error")
+)
@@ -0,0 +1,12 @@
+package unifi
+
+// Event is a single entry from a site's event, alarm or IDS/IPS log.
+type Event struct {
+	ID        string  `json:"_id"`
+	SiteName  string  `json:"-"`
+	Key       string  `json:"key"`
+	Msg       string  `json:"msg"`
+	Subsystem string  `json:"subsystem"`
+	Time      FlexInt `json:"time"`
+	Datetime  string  `json:"datetime"`
+}
@@ -0,0 +1,86 @@
+package unifi
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// SlogAdapter wraps a *slog.Logger so it satisfies LevelLogger, letting
+// callers on Go 1.21+ plug standard structured logging straight into
+// Config.StructuredLog.
+type SlogAdapter struct {
+	Logger *slog.Logger
+}
+
+// Debug implements LevelLogger.
+func (s SlogAdapter) Debug(msg string, kv ...interface{}) { s.Logger.Debug(msg, kv...) }
+
+// Info implements LevelLogger.
+func (s SlogAdapter) Info(msg string, kv ...interface{}) { s.Logger.Info(msg, kv...) }
+
+// Warn implements LevelLogger.
+func (s SlogAdapter) Warn(msg string, kv ...interface{}) { s.Logger.Warn(msg, kv...) }
+
+// Error implements LevelLogger.
+func (s SlogAdapter) Error(msg string, kv ...interface{}) { s.Logger.Error(msg, kv...) }
+
+// legacyLogAdapter shims the old printf-style ErrorLog/DebugLog Config
+// fields into LevelLogger, so the request path only ever talks to one
+// logging abstraction regardless of which the caller configured.
+type legacyLogAdapter struct {
+	errorLog Logger
+	debugLog Logger
+}
+
+// Debug implements LevelLogger.
+func (l legacyLogAdapter) Debug(msg string, kv ...interface{}) { l.debugLog(formatKV(msg, kv)) }
+
+// Info implements LevelLogger.
+func (l legacyLogAdapter) Info(msg string, kv ...interface{}) { l.debugLog(formatKV(msg, kv)) }
+
+// Warn implements LevelLogger.
+func (l legacyLogAdapter) Warn(msg string, kv ...interface{}) { l.errorLog(formatKV(msg, kv)) }
+
+// Error implements LevelLogger.
+func (l legacyLogAdapter) Error(msg string, kv ...interface{}) { l.errorLog(formatKV(msg, kv)) }
+
+// formatKV renders a message and its key/value pairs as a single
+// "msg key=value key=value" string with every % doubled so it carries no
+// format verbs, since Logger is a printf-style func and the kv pairs it's
+// handed here aren't matched up with verbs in msg.
+func formatKV(msg string, kv []interface{}) string {
+	if len(kv) == 0 {
+		return strings.ReplaceAll(msg, "%", "%%")
+	}
+
+	var b strings.Builder
+
+	b.WriteString(msg)
+
+	for i := 0; i < len(kv); i += 2 {
+		key := kv[i]
+
+		var val interface{} = "MISSING"
+		if i+1 < len(kv) {
+			val = kv[i+1]
+		}
+
+		b.WriteString(" ")
+		b.WriteString(fmt.Sprint(key))
+		b.WriteString("=")
+		b.WriteString(fmt.Sprint(val))
+	}
+
+	return strings.ReplaceAll(b.String(), "%", "%%")
+}
+
+// log returns the LevelLogger this client should use: Config.StructuredLog
+// when set, otherwise the legacy ErrorLog/DebugLog funcs shimmed into one.
+func (u *Unifi) log() LevelLogger {
+	if u.StructuredLog != nil {
+		return u.StructuredLog
+	}
+
+	return legacyLogAdapter{errorLog: u.ErrorLog, debugLog: u.DebugLog}
+}
@@ -0,0 +1,13 @@
+package unifi
+
+// Site represents a site on the Unifi controller. A controller may manage
+// more than one site; most API paths are scoped to a single site name.
+type Site struct {
+	Name         string   `json:"name"`
+	Desc         string   `json:"desc"`
+	SiteID       string   `json:"_id"`
+	Role         string   `json:"role"`
+	NumNew       FlexInt  `json:"num_new_alarms"`
+	AttrHiddenID string   `json:"attr_hidden_id"`
+	AttrNoDelete FlexBool `json:"attr_no_delete"`
+}
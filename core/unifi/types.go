@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 )
@@ -65,6 +66,10 @@ func (u *Unifi) path(path string) string {
 
 // Logger is a base type to deal with changing log outputs. Create a logger
 // that matches this interface to capture debug and error logs.
+//
+// Deprecated: Logger only supports printf-style messages at two implicit
+// levels. Prefer LevelLogger and Config.StructuredLog; Logger is kept for
+// backward compatibility and is shimmed into LevelLogger internally.
 type Logger func(msg string, fmt ...interface{})
 
 // discardLogs is the default debug logger.
@@ -72,6 +77,17 @@ func discardLogs(msg string, v ...interface{}) {
 	// do nothing.
 }
 
+// LevelLogger is a leveled, structured logging interface. Implement this
+// (or wrap a *slog.Logger with SlogAdapter) and set it as Config.StructuredLog
+// to capture request-path events with key/value attributes like site,
+// device_mac, path, status and duration_ms instead of printf-style strings.
+type LevelLogger interface {
+	Debug(msg string, kv ...interface{})
+	Info(msg string, kv ...interface{})
+	Warn(msg string, kv ...interface{})
+	Error(msg string, kv ...interface{})
+}
+
 // Devices contains a list of all the unifi devices from a controller.
 // Contains Access points, security gateways and switches.
 type Devices struct {
@@ -84,13 +100,30 @@ type Devices struct {
 // Config is the data passed into our library. This configures things and allows
 // us to connect to a controller and write log messages.
 type Config struct {
-	User      string
-	Pass      string
+	User string
+	Pass string
+	// APIKey, when set, authenticates with an X-API-Key header instead of
+	// the cookie-based User/Pass login. This is how newer UniFi OS
+	// controllers (9.x on UDM/UDR/Cloud Gateway) issue long-lived local
+	// API keys. APIKey takes precedence over User/Pass: if it is set,
+	// Login() never POSTs to the login endpoint.
+	APIKey    string
 	URL       string
 	VerifySSL bool
 	New       bool
 	ErrorLog  Logger
 	DebugLog  Logger
+	// MaxRetries caps how many times doRequest retries a request that gets
+	// a 429 or 5xx response. A zero value uses a built-in default.
+	MaxRetries int
+	// RetryBaseDelay is the base delay doRequest backs off by (doubled on
+	// each retry, plus jitter) before retrying a 429 or 5xx response. A
+	// zero value uses a built-in default.
+	RetryBaseDelay time.Duration
+	// StructuredLog, when set, takes precedence over ErrorLog/DebugLog and
+	// receives leveled, key/value request-path events (request start,
+	// retry, reauth, decode failure) instead of printf-style messages.
+	StructuredLog LevelLogger
 }
 
 // Unifi is what you get in return for providing a password! Unifi represents
@@ -134,6 +167,17 @@ func (f *FlexInt) UnmarshalJSON(b []byte) error {
 	case string:
 		f.Txt = i
 		f.Val, _ = strconv.ParseFloat(i, 64)
+	case bool:
+		// Some newer Unifi endpoints return true/false for fields that
+		// used to be numeric. Map true->1, false->0 like everywhere else
+		// that treats 0/1 as a boolean.
+		if i {
+			f.Val = 1
+			f.Txt = "1"
+		} else {
+			f.Val = 0
+			f.Txt = "0"
+		}
 	case nil:
 		f.Txt = "0"
 		f.Val = 0
@@ -144,10 +188,76 @@ func (f *FlexInt) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
+// MarshalJSON turns a FlexInt back into a JSON number, so a struct that
+// embeds one round-trips through re-serialization instead of emitting an
+// empty object. It uses Val when formatting it reproduces Txt exactly;
+// otherwise it emits Txt verbatim to avoid losing precision, provided Txt
+// parses to Val and is valid JSON number grammar, and falls back to Val
+// when it is not.
+func (f FlexInt) MarshalJSON() ([]byte, error) {
+	if f.Txt == "" || strconv.FormatFloat(f.Val, 'f', -1, 64) == f.Txt {
+		return json.Marshal(f.Val)
+	}
+
+	// Txt didn't round-trip through Val byte-for-byte (e.g. very large
+	// numbers lose precision going through float64). Prefer it verbatim,
+	// but only if it actually agrees with Val - if the two have diverged
+	// (say Val was mutated directly), Val wins since it's the field most
+	// callers expect to be authoritative. Txt must also be valid JSON
+	// number grammar on its own: it may have arrived via UnmarshalJSON's
+	// string branch (e.g. "007", "+5") which parses fine with ParseFloat
+	// but isn't legal JSON, so emitting it raw would produce invalid output.
+	// ErrRange is tolerated here (e.g. "1e400"): both Val and parsed
+	// overflow to the same +/-Inf, and Txt is our only way to reproduce a
+	// numeric value at all since json.Marshal rejects infinities.
+	parsed, err := strconv.ParseFloat(f.Txt, 64)
+	if numErr, ok := err.(*strconv.NumError); ok && numErr.Err == strconv.ErrRange {
+		err = nil
+	}
+
+	if err == nil && parsed == f.Val && isJSONNumber(f.Txt) {
+		return []byte(f.Txt), nil
+	}
+
+	return json.Marshal(f.Val)
+}
+
+// isJSONNumber reports whether s is valid JSON number grammar, so it can be
+// emitted as a raw JSON literal without producing invalid output.
+func isJSONNumber(s string) bool {
+	if !json.Valid([]byte(s)) {
+		return false
+	}
+
+	var n json.Number
+	if err := json.Unmarshal([]byte(s), &n); err != nil {
+		return false
+	}
+
+	return n.String() == s
+}
+
 func (f *FlexInt) String() string {
 	return f.Txt
 }
 
+// Int returns the FlexInt's value as an int, truncating any fractional part.
+func (f *FlexInt) Int() int {
+	return int(f.Val)
+}
+
+// Int64 returns the FlexInt's value as an int64, truncating any fractional part.
+func (f *FlexInt) Int64() int64 {
+	return int64(f.Val)
+}
+
+// Uint64 returns the FlexInt's value as a uint64, truncating any fractional
+// part. For a negative value, or one too large for a uint64, the result is
+// implementation-dependent, as with any Go float-to-integer conversion.
+func (f *FlexInt) Uint64() uint64 {
+	return uint64(f.Val)
+}
+
 // FlexBool provides a container and unmarshalling for fields that may be
 // boolean or strings in the Unifi API.
 type FlexBool struct {
@@ -167,6 +277,18 @@ func (f *FlexBool) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
+// MarshalJSON turns a FlexBool back into a JSON boolean, so a struct that
+// embeds one round-trips through re-serialization instead of emitting an
+// empty object.
+func (f FlexBool) MarshalJSON() ([]byte, error) {
+	return json.Marshal(f.Val)
+}
+
 func (f *FlexBool) String() string {
 	return f.Txt
 }
+
+// Bool returns the FlexBool's value as a bool.
+func (f *FlexBool) Bool() bool {
+	return f.Val
+}
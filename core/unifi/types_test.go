@@ -0,0 +1,208 @@
+package unifi
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestFlexIntUnmarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		input   string
+		wantVal float64
+		wantTxt string
+		wantErr bool
+	}{
+		{name: "number", input: `42`, wantVal: 42, wantTxt: "42"},
+		{name: "string", input: `"42"`, wantVal: 42, wantTxt: "42"},
+		{name: "null", input: `null`, wantVal: 0, wantTxt: "0"},
+		{name: "true", input: `true`, wantVal: 1, wantTxt: "1"},
+		{name: "false", input: `false`, wantVal: 0, wantTxt: "0"},
+		{name: "object", input: `{}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var f FlexInt
+
+			err := json.Unmarshal([]byte(tt.input), &f)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got none")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if f.Val != tt.wantVal || f.Txt != tt.wantTxt {
+				t.Errorf("got {%v %q}, want {%v %q}", f.Val, f.Txt, tt.wantVal, tt.wantTxt)
+			}
+		})
+	}
+}
+
+func TestFlexIntMarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	f := FlexInt{Val: 42, Txt: "42"}
+
+	b, err := json.Marshal(f)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if string(b) != "42" {
+		t.Errorf("got %s, want 42", b)
+	}
+}
+
+func TestFlexIntMarshalJSONFromText(t *testing.T) {
+	t.Parallel()
+
+	// These exercise the Txt-fallback branch with strings that ParseFloat
+	// accepts but that aren't legal JSON number grammar, plus a value that
+	// overflows float64. All of them arrive via the string branch of
+	// UnmarshalJSON, e.g. `"007"`.
+	tests := []struct {
+		name string
+		txt  string
+		want string
+	}{
+		{name: "zero-padded", txt: "007", want: "7"},
+		{name: "explicit sign", txt: "+5", want: "5"},
+		{name: "overflow", txt: "1e400", want: "1e400"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var f FlexInt
+			if err := json.Unmarshal([]byte(`"`+tt.txt+`"`), &f); err != nil {
+				t.Fatalf("Unmarshal(%q): %v", tt.txt, err)
+			}
+
+			b, err := json.Marshal(f)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if string(b) != tt.want {
+				t.Errorf("got %s, want %s", b, tt.want)
+			}
+		})
+	}
+}
+
+func TestFlexIntRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	for _, input := range []string{`42`, `"42"`, `null`, `true`, `false`} {
+		var f FlexInt
+		if err := json.Unmarshal([]byte(input), &f); err != nil {
+			t.Fatalf("Unmarshal(%s): %v", input, err)
+		}
+
+		b, err := json.Marshal(f)
+		if err != nil {
+			t.Fatalf("Marshal(%+v): %v", f, err)
+		}
+
+		var rt FlexInt
+		if err := json.Unmarshal(b, &rt); err != nil {
+			t.Fatalf("round-trip Unmarshal(%s): %v", b, err)
+		}
+
+		if rt.Val != f.Val {
+			t.Errorf("input %s: round-tripped Val = %v, want %v", input, rt.Val, f.Val)
+		}
+	}
+}
+
+func TestFlexIntAccessors(t *testing.T) {
+	t.Parallel()
+
+	f := FlexInt{Val: 42.9}
+
+	if got := f.Int(); got != 42 {
+		t.Errorf("Int() = %d, want 42", got)
+	}
+
+	if got := f.Int64(); got != 42 {
+		t.Errorf("Int64() = %d, want 42", got)
+	}
+
+	if got := f.Uint64(); got != 42 {
+		t.Errorf("Uint64() = %d, want 42", got)
+	}
+}
+
+func TestFlexBoolUnmarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		input string
+		want  bool
+	}{
+		{name: "one", input: `"1"`, want: true},
+		{name: "zero", input: `"0"`, want: false},
+		{name: "true string", input: `"true"`, want: true},
+		{name: "yes", input: `"yes"`, want: true},
+		{name: "armed", input: `"armed"`, want: true},
+		{name: "active", input: `"active"`, want: true},
+		{name: "disarmed", input: `"disarmed"`, want: false},
+		{name: "no", input: `"no"`, want: false},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var f FlexBool
+			if err := json.Unmarshal([]byte(tt.input), &f); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if f.Val != tt.want {
+				t.Errorf("Val = %v, want %v", f.Val, tt.want)
+			}
+		})
+	}
+}
+
+func TestFlexBoolMarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	for _, want := range []bool{true, false} {
+		f := FlexBool{Val: want}
+
+		b, err := json.Marshal(f)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		var rt FlexBool
+		if err := json.Unmarshal(b, &rt); err != nil {
+			t.Fatalf("round-trip Unmarshal(%s): %v", b, err)
+		}
+
+		if rt.Bool() != want {
+			t.Errorf("round-tripped Bool() = %v, want %v", rt.Bool(), want)
+		}
+	}
+}
@@ -0,0 +1,420 @@
+package unifi
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/http/cookiejar"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// NewUnifi creates an http.Client, points it at the controller described by
+// c, and logs in. The returned Unifi is ready to make authenticated requests.
+func NewUnifi(c *Config) (*Unifi, error) {
+	if c.ErrorLog == nil {
+		c.ErrorLog = discardLogs
+	}
+
+	if c.DebugLog == nil {
+		c.DebugLog = discardLogs
+	}
+
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "cookiejar.New")
+	}
+
+	u := &Unifi{
+		Config: c,
+		server: &server{},
+		Client: &http.Client{
+			Jar: jar,
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: !c.VerifySSL}, //nolint:gosec
+			},
+		},
+	}
+
+	return u, u.Login()
+}
+
+// Login authenticates with the Unifi Controller and stores the session
+// cookie (and CSRF token) used by subsequent requests.
+func (u *Unifi) Login() error {
+	return u.LoginContext(context.Background())
+}
+
+// LoginContext is Login with the addition of a context, which may be used
+// to cancel the login request or apply a timeout to it.
+func (u *Unifi) LoginContext(ctx context.Context) error {
+	if u.APIKey != "" {
+		// API-key auth carries its own header on every request, so there's
+		// no session to establish. We still need to know which path prefix
+		// to use, so probe for it unless Config.New is already set.
+		if !u.New {
+			if err := u.probeNewContext(ctx); err != nil {
+				return errors.Wrap(err, "probing controller API prefix")
+			}
+		}
+
+		u.log().Info("api key auth", "new", u.New)
+
+		return nil
+	}
+
+	params := fmt.Sprintf(`{"username":%q,"password":%q}`, u.User, u.Pass)
+
+	req, err := u.UniReqContext(ctx, u.path(APILoginPath), params)
+	if err != nil {
+		return errors.Wrap(err, "building login request")
+	}
+
+	resp, err := u.Do(req) //nolint:bodyclose
+	if err != nil {
+		return errors.Wrap(err, "authentication request failed")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("authentication failed (status: %s)", resp.Status)
+	}
+
+	if csrf := resp.Header.Get("X-CSRF-Token"); csrf != "" {
+		u.csrf = csrf
+	}
+
+	u.log().Info("login", "user", u.User)
+
+	return nil
+}
+
+// probeNewContext determines whether the controller expects the newer
+// /proxy/network path prefix (UniFi OS / UDM-style controllers) by
+// requesting the status endpoint with the prefix applied. It's only called
+// when Config.New was left unset and an APIKey is in use; the cookie-based
+// login does not probe and uses Config.New as given.
+func (u *Unifi) probeNewContext(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.URL+APIPrefixNew+APIStatusPath, nil)
+	if err != nil {
+		return errors.Wrap(err, "http.NewRequestWithContext")
+	}
+
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("X-API-Key", u.APIKey)
+
+	resp, err := u.Do(req) //nolint:bodyclose
+	if err != nil {
+		return errors.Wrap(err, "probe request failed")
+	}
+	defer resp.Body.Close()
+
+	u.New = resp.StatusCode == http.StatusOK
+
+	return nil
+}
+
+// UniReq builds an *http.Request for a Unifi controller API path. When
+// params is non-empty the request is a POST carrying params as the JSON
+// body; otherwise it's a GET.
+func (u *Unifi) UniReq(apiPath string, params string) (*http.Request, error) {
+	return u.UniReqContext(context.Background(), apiPath, params)
+}
+
+// UniReqContext is UniReq with the addition of a context. The context is
+// attached to the returned request via http.NewRequestWithContext so a
+// caller can cancel it or apply a deadline.
+func (u *Unifi) UniReqContext(ctx context.Context, apiPath string, params string) (*http.Request, error) {
+	method := http.MethodGet
+	if params != "" {
+		method = http.MethodPost
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.URL+u.path(apiPath), bodyReader(params))
+	if err != nil {
+		return nil, errors.Wrap(err, "http.NewRequestWithContext")
+	}
+
+	u.setRequestHeaders(req)
+
+	return req, nil
+}
+
+// GetData unmarshals the "data" member of the response from apiPath into v.
+func (u *Unifi) GetData(apiPath string, v interface{}) error {
+	return u.GetDataContext(context.Background(), apiPath, v)
+}
+
+// GetDataContext is GetData with the addition of a context.
+func (u *Unifi) GetDataContext(ctx context.Context, apiPath string, v interface{}) error {
+	return u.getDataContext(ctx, apiPath, "", v)
+}
+
+// getDataContext is the internal helper every Get* method funnels through.
+func (u *Unifi) getDataContext(ctx context.Context, apiPath, params string, v interface{}) error {
+	method := http.MethodGet
+	if params != "" {
+		method = http.MethodPost
+	}
+
+	return u.doRequest(ctx, method, apiPath, params, v)
+}
+
+const (
+	defaultMaxRetries     = 3
+	defaultRetryBaseDelay = 500 * time.Millisecond
+)
+
+// doRequest is the single place every request in this package eventually
+// goes through. It builds the request, executes it, and:
+//   - on a 401/403, invalidates the session and calls Login() once, then
+//     replays the request (with an APIKey set there is no session, so it
+//     returns ErrUnauthorized straight away);
+//   - on a 429 or 5xx, retries with exponential backoff and jitter, up to
+//     Config.MaxRetries times;
+//   - decodes the "data" envelope the controller wraps every response in
+//     into out, unless out is nil.
+func (u *Unifi) doRequest(ctx context.Context, method, apiPath, body string, out interface{}) error {
+	maxRetries := u.MaxRetries
+	if maxRetries <= 0 {
+		maxRetries = defaultMaxRetries
+	}
+
+	reauthed := false
+
+	for attempt := 0; ; attempt++ {
+		start := time.Now()
+
+		req, err := u.UniReqContext(ctx, apiPath, body)
+		if err != nil {
+			return errors.Wrap(err, "building request")
+		}
+
+		u.log().Debug("request start", "method", method, "path", apiPath, "attempt", attempt)
+
+		resp, err := u.Do(req) //nolint:bodyclose
+		if err != nil {
+			u.log().Error("request failed", "method", method, "path", apiPath, "error", err)
+			return errors.Wrapf(ErrTransport, "%s: %s", apiPath, err)
+		}
+
+		respBody, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		durationMS := time.Since(start).Milliseconds()
+
+		if err != nil {
+			return errors.Wrap(err, "reading response body")
+		}
+
+		switch {
+		case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+			if reauthed || u.APIKey != "" {
+				return errors.Wrapf(ErrUnauthorized, "%s (status: %s)", apiPath, resp.Status)
+			}
+
+			u.log().Warn("reauthenticating", "path", apiPath, "status", resp.StatusCode)
+
+			u.csrf = ""
+
+			if err := u.LoginContext(ctx); err != nil {
+				return errors.Wrap(err, "re-authenticating")
+			}
+
+			reauthed = true
+
+			continue
+		case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError:
+			if attempt >= maxRetries {
+				if resp.StatusCode == http.StatusTooManyRequests {
+					return errors.Wrapf(ErrRateLimited, "%s (status: %s)", apiPath, resp.Status)
+				}
+
+				return errors.Wrapf(ErrTransport, "%s (status: %s)", apiPath, resp.Status)
+			}
+
+			u.log().Warn("retrying", "path", apiPath, "status", resp.StatusCode, "attempt", attempt)
+
+			if err := u.backoffSleep(ctx, attempt); err != nil {
+				return err
+			}
+
+			continue
+		case resp.StatusCode != http.StatusOK:
+			return errors.Errorf("request to %s failed (status: %s): %s", apiPath, resp.Status, respBody)
+		}
+
+		u.log().Debug("request complete", "path", apiPath, "status", resp.StatusCode, "duration_ms", durationMS)
+
+		if out == nil {
+			return nil
+		}
+
+		var response struct {
+			Data json.RawMessage `json:"data"`
+		}
+
+		if err := json.Unmarshal(respBody, &response); err != nil {
+			u.log().Error("decode failed", "path", apiPath, "error", err)
+			return errors.Wrapf(err, "unmarshaling response from %s", apiPath)
+		}
+
+		if err := json.Unmarshal(response.Data, out); err != nil {
+			u.log().Error("decode failed", "path", apiPath, "error", err)
+			return errors.Wrapf(err, "unmarshaling data from %s", apiPath)
+		}
+
+		return nil
+	}
+}
+
+// backoffSleep waits out an exponential backoff (with jitter) before the
+// next retry attempt, returning early if ctx is canceled.
+func (u *Unifi) backoffSleep(ctx context.Context, attempt int) error {
+	base := u.RetryBaseDelay
+	if base <= 0 {
+		base = defaultRetryBaseDelay
+	}
+
+	delay := base * time.Duration(1<<uint(attempt)) //nolint:gosec
+	delay += time.Duration(rand.Int63n(int64(base) + 1))
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// bodyReader returns an io.Reader for an HTTP request body, or nil when
+// body is empty, matching http.NewRequestWithContext's expectations.
+func bodyReader(body string) io.Reader {
+	if body == "" {
+		return nil
+	}
+
+	return strings.NewReader(body)
+}
+
+// setRequestHeaders applies the standard Content-Type/Accept headers plus
+// whichever of X-API-Key or X-CSRF-Token applies to this client.
+func (u *Unifi) setRequestHeaders(req *http.Request) {
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	switch {
+	case u.APIKey != "":
+		// API-key auth replaces the session cookie and CSRF token entirely.
+		req.Header.Set("X-API-Key", u.APIKey)
+	case u.csrf != "":
+		req.Header.Set("X-CSRF-Token", u.csrf)
+	}
+}
+
+// GetSites returns the list of sites configured on the controller.
+func (u *Unifi) GetSites() ([]*Site, error) {
+	return u.GetSitesContext(context.Background())
+}
+
+// GetSitesContext is GetSites with the addition of a context.
+func (u *Unifi) GetSitesContext(ctx context.Context) ([]*Site, error) {
+	var sites []*Site
+
+	if err := u.getDataContext(ctx, APISiteList, "", &sites); err != nil {
+		return nil, err
+	}
+
+	return sites, nil
+}
+
+// GetClients returns the clients connected to the given sites.
+func (u *Unifi) GetClients(sites []*Site) ([]*Client, error) {
+	return u.GetClientsContext(context.Background(), sites)
+}
+
+// GetClientsContext is GetClients with the addition of a context.
+func (u *Unifi) GetClientsContext(ctx context.Context, sites []*Site) ([]*Client, error) {
+	clients := []*Client{}
+
+	for _, site := range sites {
+		var siteClients []*Client
+
+		apiPath := fmt.Sprintf(APIClientPath, site.Name)
+		if err := u.getDataContext(ctx, apiPath, "", &siteClients); err != nil {
+			return nil, errors.Wrapf(err, "site %s", site.Name)
+		}
+
+		for _, c := range siteClients {
+			c.SiteName = site.Name
+		}
+
+		clients = append(clients, siteClients...)
+	}
+
+	return clients, nil
+}
+
+// GetDevices returns the devices, grouped by type, for the given sites.
+func (u *Unifi) GetDevices(sites []*Site) (*Devices, error) {
+	return u.GetDevicesContext(context.Background(), sites)
+}
+
+// GetDevicesContext is GetDevices with the addition of a context.
+func (u *Unifi) GetDevicesContext(ctx context.Context, sites []*Site) (*Devices, error) {
+	devices := &Devices{}
+
+	for _, site := range sites {
+		var raw []json.RawMessage
+
+		apiPath := fmt.Sprintf(APIDevicePath, site.Name)
+		if err := u.getDataContext(ctx, apiPath, "", &raw); err != nil {
+			return nil, errors.Wrapf(err, "site %s", site.Name)
+		}
+
+		if err := devices.addRaw(raw, site.Name); err != nil {
+			return nil, errors.Wrapf(err, "site %s", site.Name)
+		}
+	}
+
+	return devices, nil
+}
+
+// GetEvents returns the events that occurred on the given sites in the
+// last `hours` hours.
+func (u *Unifi) GetEvents(sites []*Site, hours int) ([]*Event, error) {
+	return u.GetEventsContext(context.Background(), sites, hours)
+}
+
+// GetEventsContext is GetEvents with the addition of a context.
+func (u *Unifi) GetEventsContext(ctx context.Context, sites []*Site, hours int) ([]*Event, error) {
+	events := []*Event{}
+
+	for _, site := range sites {
+		var siteEvents []*Event
+
+		apiPath := fmt.Sprintf(APIEventPath, site.Name)
+		params := fmt.Sprintf(`{"within":%d,"_limit":5000}`, hours)
+
+		if err := u.getDataContext(ctx, apiPath, params, &siteEvents); err != nil {
+			return nil, errors.Wrapf(err, "site %s", site.Name)
+		}
+
+		for _, e := range siteEvents {
+			e.SiteName = site.Name
+		}
+
+		events = append(events, siteEvents...)
+	}
+
+	return events, nil
+}
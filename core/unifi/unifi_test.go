@@ -0,0 +1,118 @@
+package unifi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestUniReqContextAPIKey(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		apiKey     string
+		new        bool
+		wantPrefix string
+	}{
+		{name: "classic controller", apiKey: "super-secret-key", new: false, wantPrefix: ""},
+		{name: "unifi os controller", apiKey: "super-secret-key", new: true, wantPrefix: APIPrefixNew},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			u := &Unifi{
+				Config: &Config{URL: "https://127.0.0.1:8443", APIKey: tt.apiKey, New: tt.new},
+			}
+
+			req, err := u.UniReqContext(context.Background(), APISiteList, "")
+			if err != nil {
+				t.Fatalf("UniReqContext() error = %v", err)
+			}
+
+			if got := req.Header.Get("X-API-Key"); got != tt.apiKey {
+				t.Errorf("X-API-Key header = %q, want %q", got, tt.apiKey)
+			}
+
+			if got := req.Header.Get("X-CSRF-Token"); got != "" {
+				t.Errorf("X-CSRF-Token header = %q, want empty when using an API key", got)
+			}
+
+			wantURL := u.URL + tt.wantPrefix + APISiteList
+			if req.URL.String() != wantURL {
+				t.Errorf("request URL = %q, want %q", req.URL.String(), wantURL)
+			}
+		})
+	}
+}
+
+func TestLoginContextAPIKeyProbe(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		statusCode int
+		wantNew    bool
+	}{
+		{name: "unifi os prefix found", statusCode: http.StatusOK, wantNew: true},
+		{name: "classic controller, no prefix", statusCode: http.StatusNotFound, wantNew: false},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != APIPrefixNew+APIStatusPath {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+
+				w.WriteHeader(tt.statusCode)
+			}))
+			defer ts.Close()
+
+			u := &Unifi{
+				Config: &Config{URL: ts.URL, APIKey: "super-secret-key", ErrorLog: discardLogs, DebugLog: discardLogs},
+				Client: ts.Client(),
+			}
+
+			if err := u.LoginContext(context.Background()); err != nil {
+				t.Fatalf("LoginContext() error = %v", err)
+			}
+
+			if u.New != tt.wantNew {
+				t.Errorf("New = %v, want %v", u.New, tt.wantNew)
+			}
+		})
+	}
+}
+
+func TestUniReqContextCSRF(t *testing.T) {
+	t.Parallel()
+
+	u := &Unifi{
+		Config: &Config{URL: "https://127.0.0.1:8443"},
+		csrf:   "test-csrf-token",
+	}
+
+	req, err := u.UniReqContext(context.Background(), APISiteList, "")
+	if err != nil {
+		t.Fatalf("UniReqContext() error = %v", err)
+	}
+
+	if got := req.Header.Get("X-CSRF-Token"); got != u.csrf {
+		t.Errorf("X-CSRF-Token header = %q, want %q", got, u.csrf)
+	}
+
+	if got := req.Header.Get("X-API-Key"); got != "" {
+		t.Errorf("X-API-Key header = %q, want empty when no APIKey is configured", got)
+	}
+}